@@ -0,0 +1,230 @@
+//go:build js && wasm
+
+// Package bridge gives GoFlow apps a clean way to expose Go functions to
+// JavaScript, and to await JS promises from Go, without each app hand
+// rolling js.FuncOf/Promise boilerplate.
+package bridge
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"syscall/js"
+)
+
+// globalName is the single object under which every exposed function is
+// attached. The index.html loader exposes a separate Proxy, window.goflow,
+// so JS callers can await goflow.myFunc(...) even before Ready has been
+// called; __goflow__ itself is a plain object, and calling a function on
+// it before Expose has attached that function fails.
+const globalName = "__goflow__"
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// namespace returns the global bridge object, creating it (with
+// __ready__ false) the first time it's needed.
+func namespace() js.Value {
+	global := js.Global()
+	ns := global.Get(globalName)
+	if !ns.Truthy() {
+		ns = js.Global().Get("Object").New()
+		ns.Set("__ready__", false)
+		global.Set(globalName, ns)
+	}
+	return ns
+}
+
+// Ready flips the bridge's __ready__ flag so queued JS calls made before
+// Wasm finished loading are flushed. Call it once main has exposed
+// everything it needs to.
+func Ready() {
+	namespace().Set("__ready__", true)
+}
+
+// Expose attaches fn to the global bridge object under name, so
+// JavaScript can call it as `await __goflow__.name(...)`.
+//
+// fn's parameters are unmarshaled from the JS arguments: string, the
+// signed integer and float kinds, bool, and []byte (from a Uint8Array)
+// convert directly; everything else (unsigned integers, structs, maps,
+// slices of those) round
+// trips through encoding/json. If fn returns (T, error), the call
+// resolves with T or rejects with error; a bare T or a bare error is
+// also accepted.
+func Expose(name string, fn any) {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func {
+		panic(fmt.Sprintf("bridge: Expose(%q): fn must be a function, got %T", name, fn))
+	}
+
+	wrapper := js.FuncOf(func(this js.Value, args []js.Value) any {
+		return callExposed(value, args)
+	})
+	namespace().Set(name, wrapper)
+}
+
+// callExposed invokes fn on a fresh goroutine (so it can block, e.g. to
+// Await a JS promise) and returns a JS Promise that settles with its
+// result.
+func callExposed(fn reflect.Value, args []js.Value) any {
+	fnType := fn.Type()
+
+	executor := js.FuncOf(func(this js.Value, executorArgs []js.Value) any {
+		resolve, reject := executorArgs[0], executorArgs[1]
+
+		go func() {
+			in, err := unmarshalArgs(fnType, args)
+			if err != nil {
+				reject.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+
+			result, err := splitResult(fnType, fn.Call(in))
+			if err != nil {
+				reject.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			resolve.Invoke(result)
+		}()
+
+		return nil
+	})
+	// The Promise constructor calls executor synchronously, so it's
+	// safe to release right after construction.
+	promise := js.Global().Get("Promise").New(executor)
+	executor.Release()
+	return promise
+}
+
+func unmarshalArgs(fnType reflect.Type, args []js.Value) ([]reflect.Value, error) {
+	if fnType.NumIn() != len(args) {
+		return nil, fmt.Errorf("expected %d arguments, got %d", fnType.NumIn(), len(args))
+	}
+	in := make([]reflect.Value, fnType.NumIn())
+	for i := 0; i < fnType.NumIn(); i++ {
+		v, err := unmarshalArg(fnType.In(i), args[i])
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i, err)
+		}
+		in[i] = v
+	}
+	return in, nil
+}
+
+func unmarshalArg(paramType reflect.Type, arg js.Value) (reflect.Value, error) {
+	switch paramType.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(arg.String()).Convert(paramType), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.ValueOf(arg.Int()).Convert(paramType), nil
+	case reflect.Float32, reflect.Float64:
+		return reflect.ValueOf(arg.Float()).Convert(paramType), nil
+	case reflect.Bool:
+		return reflect.ValueOf(arg.Bool()).Convert(paramType), nil
+	case reflect.Slice:
+		if paramType.Elem().Kind() == reflect.Uint8 {
+			bytes := make([]byte, arg.Get("length").Int())
+			js.CopyBytesToGo(bytes, arg)
+			return reflect.ValueOf(bytes), nil
+		}
+		return unmarshalJSON(paramType, arg)
+	default:
+		return unmarshalJSON(paramType, arg)
+	}
+}
+
+// unmarshalJSON handles structs, maps, and anything else not covered by
+// unmarshalArg's fast paths by round tripping through JSON.stringify.
+func unmarshalJSON(paramType reflect.Type, arg js.Value) (reflect.Value, error) {
+	raw := js.Global().Get("JSON").Call("stringify", arg).String()
+	target := reflect.New(paramType)
+	if err := json.Unmarshal([]byte(raw), target.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return target.Elem(), nil
+}
+
+// splitResult separates fn's return values into the value to resolve
+// the promise with and, if the last return value is a non-nil error,
+// the error to reject it with instead.
+func splitResult(fnType reflect.Type, out []reflect.Value) (any, error) {
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	last := len(out) - 1
+	if fnType.Out(last).Implements(errorType) {
+		if !out[last].IsNil() {
+			return nil, out[last].Interface().(error)
+		}
+		if last == 0 {
+			return nil, nil
+		}
+		return toJS(out[0].Interface()), nil
+	}
+
+	return toJS(out[0].Interface()), nil
+}
+
+// toJS converts a Go return value into something js.Value.Invoke can
+// hand to JS directly: primitives and []byte pass through (the latter
+// as a Uint8Array), everything else round trips through JSON.parse.
+func toJS(value any) any {
+	switch v := value.(type) {
+	case nil, bool, string,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return v
+	case []byte:
+		array := js.Global().Get("Uint8Array").New(len(v))
+		js.CopyBytesToJS(array, v)
+		return array
+	default:
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return js.Null()
+		}
+		return js.Global().Get("JSON").Call("parse", string(raw))
+	}
+}
+
+// Await blocks the calling goroutine until the JS promise settles,
+// returning its resolved value or an error describing the rejection.
+func Await(promise js.Value) (js.Value, error) {
+	resultCh := make(chan js.Value, 1)
+	errCh := make(chan error, 1)
+
+	then := js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) > 0 {
+			resultCh <- args[0]
+		} else {
+			resultCh <- js.Undefined()
+		}
+		return nil
+	})
+	defer then.Release()
+
+	catch := js.FuncOf(func(this js.Value, args []js.Value) any {
+		message := "rejected with no reason"
+		if len(args) > 0 {
+			if args[0].Type() == js.TypeObject && args[0].Get("message").Truthy() {
+				message = args[0].Get("message").String()
+			} else {
+				message = args[0].String()
+			}
+		}
+		errCh <- fmt.Errorf("bridge: js promise rejected: %s", message)
+		return nil
+	})
+	defer catch.Release()
+
+	promise.Call("then", then).Call("catch", catch)
+
+	select {
+	case result := <-resultCh:
+		return result, nil
+	case err := <-errCh:
+		return js.Undefined(), err
+	}
+}
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"net/http"
 	"os"
@@ -10,7 +13,11 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/fsnotify/fsnotify"
+	"github.com/gorilla/websocket"
 	"github.com/spf13/cobra"
 )
 
@@ -27,21 +34,41 @@ var initCmd = &cobra.Command{
 	Use:   "init [project-name]",
 	Short: "Initializes a new GoFlow project.",
 	Long: `Creates a new directory with the specified project name and populates it with
-the basic structure and files needed to get started with a GoFlow application.`,
-	Args: cobra.ExactArgs(1), // Ensures exactly one argument (the project name) is passed
+the basic structure and files needed to get started with a GoFlow application.
+
+Use --template to pick a starting point other than "basic", or
+--list-templates to see what's available.`,
+	Args: cobra.MaximumNArgs(1), // project name is required unless --list-templates is set
 	Run:  runInit,
 }
 
+var (
+	initTemplate      string
+	initTemplateDir   string
+	initListTemplates bool
+)
+
 // buildCmd represents the build command
 var buildCmd = &cobra.Command{
 	Use:   "build",
 	Short: "Builds the GoFlow application into a Wasm module.",
 	Long: `Compiles the Go source code into a WebAssembly module (app.wasm) and
 copies the necessary wasm_exec.js file. This command should be run from
-the root of a GoFlow project.`,
+the root of a GoFlow project.
+
+Pass --release to instead produce a deployable bundle in dist/: a
+stripped, content-hashed app.wasm and wasm_exec.js with gzip/brotli
+sidecars, an index.html rewritten to reference them, a
+goflow-manifest.json mapping logical to hashed names, and the rest of
+the project's static assets copied in as-is.`,
 	Run: runBuild,
 }
 
+var (
+	buildRelease bool
+	buildTargets []string
+)
+
 // devCmd represents the dev command
 var devCmd = &cobra.Command{
 	Use:   "dev",
@@ -52,6 +79,13 @@ It is recommended to run 'goflow build' before starting the dev server.`,
 }
 
 func init() {
+	initCmd.Flags().StringVarP(&initTemplate, "template", "t", "basic", "template to scaffold the project from")
+	initCmd.Flags().StringVar(&initTemplateDir, "template-dir", "", "directory of custom templates to use instead of the built-in ones")
+	initCmd.Flags().BoolVar(&initListTemplates, "list-templates", false, "list the available templates and exit")
+
+	buildCmd.Flags().BoolVar(&buildRelease, "release", false, "produce a production build in dist/ instead of app.wasm")
+	buildCmd.Flags().StringArrayVar(&buildTargets, "target", nil, "os/arch pairs (e.g. linux/amd64) to cross-package a self-hosted server binary for; implies --release")
+
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(buildCmd)
 	rootCmd.AddCommand(devCmd)
@@ -64,9 +98,37 @@ func Execute() error {
 
 // runInit is the function executed when the 'init' command is called.
 func runInit(cmd *cobra.Command, args []string) {
+	src := builtinTemplates()
+	if initTemplateDir != "" {
+		src = externalTemplates(initTemplateDir)
+	}
+
+	names, err := src.list()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	if initListTemplates {
+		fmt.Println("Available templates:")
+		for _, name := range names {
+			fmt.Printf("  - %s\n", name)
+		}
+		return
+	}
+
+	if len(args) == 0 {
+		fmt.Println("❌ Missing project name. Usage: goflow init [project-name]")
+		os.Exit(1)
+	}
 	projectName := args[0]
 
-	fmt.Printf("🚀 Initializing new GoFlow project: %s\n", projectName)
+	if !containsString(names, initTemplate) {
+		fmt.Printf("❌ Unknown template %q. Available templates: %s\n", initTemplate, strings.Join(names, ", "))
+		os.Exit(1)
+	}
+
+	fmt.Printf("🚀 Initializing new GoFlow project: %s (template: %s)\n", projectName, initTemplate)
 
 	// Create project directory
 	if err := os.Mkdir(projectName, 0755); err != nil {
@@ -74,24 +136,19 @@ func runInit(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	// Define files to create with their content
-	filesToCreate := map[string]string{
-		"main.go":    mainGoTemplate,
-		"index.html": indexHTMLTemplate,
-		"go.mod":     goModTemplate(projectName),
-		"README.md":  readmeTemplate(projectName),
-		".gitignore": gitignoreTemplate,
+	data := templateData{
+		ProjectName: projectName,
+		GoVersion:   defaultGoVersion,
+		ModulePath:  projectName,
 	}
 
-	for fileName, content := range filesToCreate {
-		filePath := filepath.Join(projectName, fileName)
-		err := os.WriteFile(filePath, []byte(strings.TrimSpace(content)), 0644)
-		if err != nil {
-			fmt.Printf("❌ Error creating file %s: %v\n", fileName, err)
-			// Cleanup: attempt to remove created directory
-			os.RemoveAll(projectName)
-			os.Exit(1)
-		}
+	created, err := src.render(initTemplate, projectName, data)
+	if err != nil {
+		fmt.Printf("❌ Error scaffolding project: %v\n", err)
+		os.RemoveAll(projectName)
+		os.Exit(1)
+	}
+	for _, filePath := range created {
 		fmt.Printf("✅ Created %s\n", filePath)
 	}
 
@@ -103,6 +160,15 @@ func runInit(cmd *cobra.Command, args []string) {
 	fmt.Println("  4. Open http://localhost:8080 in your browser.")
 }
 
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
 // runBuild handles the logic for the 'goflow build' command.
 func runBuild(cmd *cobra.Command, args []string) {
 	// Check if we are in a goflow project
@@ -111,16 +177,24 @@ func runBuild(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	fmt.Println("Building Go code to WebAssembly...")
+	if buildRelease || len(buildTargets) > 0 {
+		wd, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("❌ Could not determine working directory: %v\n", err)
+			os.Exit(1)
+		}
+		if err := runRelease(filepath.Base(wd), buildTargets); err != nil {
+			fmt.Printf("❌ Release build failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("\nRelease build complete. Artifacts are in %s/\n", distDir)
+		return
+	}
 
-	// Set environment variables for the build command.
-	buildCmd := exec.Command("go", "build", "-o", "app.wasm", ".")
-	buildCmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
-	buildCmd.Stdout = os.Stdout
-	buildCmd.Stderr = os.Stderr
+	fmt.Println("Building Go code to WebAssembly...")
 
 	// Run the build command.
-	if err := buildCmd.Run(); err != nil {
+	if err := buildWasm(os.Stderr); err != nil {
 		fmt.Printf("❌ Build failed: %v\n", err)
 		os.Exit(1)
 	}
@@ -135,154 +209,322 @@ func runBuild(cmd *cobra.Command, args []string) {
 	fmt.Println("\nBuild complete. You can now serve the directory using 'goflow dev'")
 }
 
-// runDev handles the logic for the 'goflow dev' command.
+// buildWasm compiles the project's main.go to app.wasm, writing build
+// errors to stderr. It is shared by 'goflow build' and the dev server's
+// rebuild loop.
+func buildWasm(stderr io.Writer) error {
+	buildCmd := exec.Command("go", "build", "-o", "app.wasm", ".")
+	buildCmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	buildCmd.Stdout = os.Stdout
+	buildCmd.Stderr = stderr
+	return buildCmd.Run()
+}
+
+// runDev handles the logic for the 'goflow dev' command. It serves the
+// project directory, rebuilds the Wasm module whenever a watched source
+// file changes, and pushes a reload (or build-error overlay) to any
+// connected browser over a WebSocket.
 func runDev(cmd *cobra.Command, args []string) {
+	root, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("❌ Could not determine working directory: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Check if the build artifacts exist
 	if _, err := os.Stat("app.wasm"); os.IsNotExist(err) {
-		fmt.Println("⚠️ app.wasm not found. Did you run 'goflow build' first?")
+		fmt.Println("⚠️ app.wasm not found. Building now...")
 	}
 
+	hub := newReloadHub()
+	rebuild(hub)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(liveReloadPath, hub.serveWS)
+	mux.Handle("/", injectLiveReload(http.FileServer(http.Dir("."))))
+
 	port := "8080"
 	addr := ":" + port
-	fs := http.FileServer(http.Dir("."))
-	http.Handle("/", fs)
+	server := &http.Server{Addr: addr, Handler: mux}
 
-	fmt.Printf("Starting server on http://localhost:%s\n", port)
-	log.Fatal(http.ListenAndServe(addr, nil))
-}
+	go func() {
+		fmt.Printf("Starting server on http://localhost:%s\n", port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
 
-// copyWasmExec finds and copies the wasm_exec.js file.
-func copyWasmExec() error {
-	goRoot := runtime.GOROOT()
-	if goRoot == "" {
-		return fmt.Errorf("GOROOT environment variable is not set")
+	if err := watch(root, hub); err != nil {
+		fmt.Printf("❌ Watcher error: %v\n", err)
+		os.Exit(1)
 	}
+}
 
-	srcPath := filepath.Join(goRoot, "lib", "wasm", "wasm_exec.js")
-	destPath := "wasm_exec.js"
+// --- Live reload ---
 
-	srcFile, err := os.Open(srcPath)
+const liveReloadPath = "/__goflow/livereload"
+
+// watchedExts are the file extensions that trigger a rebuild.
+var watchedExts = map[string]bool{
+	".go":   true,
+	".html": true,
+	".css":  true,
+}
+
+// ignoredDirs are skipped when walking the project tree for directories
+// to watch.
+var ignoredDirs = map[string]bool{
+	".git": true, "node_modules": true, "dist": true,
+}
+
+// watch walks root registering every directory with fsnotify, then
+// debounces file-change events (200-500ms) into a single rebuild so a
+// burst of saves (e.g. from a formatter) only triggers one build.
+func watch(root string, hub *reloadHub) error {
+	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
-		return fmt.Errorf("could not open source file %s: %w", srcPath, err)
+		return fmt.Errorf("could not create watcher: %w", err)
 	}
-	defer srcFile.Close()
+	defer watcher.Close()
 
-	destFile, err := os.Create(destPath)
-	if err != nil {
-		return fmt.Errorf("could not create destination file %s: %w", destPath, err)
+	if err := addWatchDirs(watcher, root); err != nil {
+		return fmt.Errorf("could not watch %s: %w", root, err)
 	}
-	defer destFile.Close()
 
-	_, err = io.Copy(destFile, srcFile)
-	if err != nil {
-		return fmt.Errorf("could not copy file contents: %w", err)
+	var (
+		mu    sync.Mutex
+		timer *time.Timer
+	)
+	schedule := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if timer != nil {
+			timer.Stop()
+		}
+		timer = time.AfterFunc(300*time.Millisecond, func() { rebuild(hub) })
+	}
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if watchedExts[filepath.Ext(event.Name)] {
+				schedule()
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Printf("⚠️ Watcher error: %v\n", err)
+		}
 	}
-	return nil
 }
 
-// --- Templates ---
+func addWatchDirs(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if ignoredDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return watcher.Add(path)
+		}
+		return nil
+	})
+}
 
-const mainGoTemplate = `
-package main
+// buildMu serializes rebuild runs. A debounced fsnotify event can still
+// fire while the previous build is in flight (any non-trivial project
+// takes longer to compile than the debounce window), and two `go build
+// -o app.wasm .` invocations writing the same file concurrently can
+// corrupt it; buildMu makes the second rebuild wait instead.
+var buildMu sync.Mutex
+
+// rebuild recompiles the project and broadcasts the outcome to every
+// connected browser: a reload message on success, or a build-error
+// overlay with the captured stderr on failure.
+func rebuild(hub *reloadHub) {
+	buildMu.Lock()
+	defer buildMu.Unlock()
+
+	fmt.Println("🔄 Rebuilding...")
+	var stderr bytes.Buffer
+	if err := buildWasm(&stderr); err != nil {
+		fmt.Printf("❌ Build failed: %v\n", err)
+		hub.broadcast(reloadMessage{Type: "error", Message: stderr.String()})
+		return
+	}
+	fmt.Println("✅ Build successful.")
+	hub.broadcast(reloadMessage{Type: "reload"})
+}
 
-import (
-	"fmt"
-	"syscall/js"
-)
+// reloadMessage is sent to the browser over the livereload WebSocket.
+type reloadMessage struct {
+	Type    string `json:"type"`
+	Message string `json:"message,omitempty"`
+}
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
 
-func main() {
-	fmt.Println("Go Wasm app initialized.")
+// reloadHub tracks the browsers currently connected to the livereload
+// socket and broadcasts build results to all of them.
+type reloadHub struct {
+	mu      sync.Mutex
+	clients map[*websocket.Conn]bool
+}
+
+func newReloadHub() *reloadHub {
+	return &reloadHub{clients: make(map[*websocket.Conn]bool)}
+}
 
-	// Get the document object
-	document := js.Global().Get("document")
-	if !document.Truthy() {
-		fmt.Println("Could not get document object")
+func (h *reloadHub) serveWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
 		return
 	}
+	h.mu.Lock()
+	h.clients[conn] = true
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, conn)
+		h.mu.Unlock()
+		conn.Close()
+	}()
+
+	// Drain incoming messages (there are none we care about) so the
+	// read loop notices when the browser disconnects.
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
 
-	// Get the app container
-	appContainer := document.Call("getElementById", "app")
-	if !appContainer.Truthy() {
-		fmt.Println("Could not find element with id 'app'")
+func (h *reloadHub) broadcast(msg reloadMessage) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
 		return
 	}
 
-	// Create a new element
-	h1 := document.Call("createElement", "h1")
-	h1.Set("textContent", "Hello, GoFlow! 🚀")
-
-	// Append the new element to the container
-	appContainer.Call("appendChild", h1)
-
-	// Keep the Go program running
-	<-make(chan bool)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for conn := range h.clients {
+		if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+			conn.Close()
+			delete(h.clients, conn)
+		}
+	}
 }
-`
 
-const indexHTMLTemplate = `
-<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>GoFlow App</title>
-    <style>
-        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, Helvetica, Arial, sans-serif; display: flex; justify-content: center; align-items: center; height: 100vh; margin: 0; background-color: #f0f2f5; }
-        #app { text-align: center; }
-    </style>
-</head>
-<body>
-    <div id="app">
-        <h2>Loading WebAssembly...</h2>
-		<p>If you see this message, the Go Wasm module is loading or has failed to load. Check the browser console for errors.</p>
-    </div>
-
-    <!-- The JS glue file provided by the Go installation -->
-    <script src="wasm_exec.js"></script>
-    <script>
-        if (!WebAssembly.instantiateStreaming) { // polyfill
-            WebAssembly.instantiateStreaming = async (resp, importObject) => {
-                const source = await (await resp).arrayBuffer();
-                return await WebAssembly.instantiate(source, importObject);
-            };
-        }
-
-        const go = new Go();
-        WebAssembly.instantiateStreaming(fetch("app.wasm"), go.importObject).then((result) => {
-            go.run(result.instance);
-        }).catch((err) => {
-            console.error("Wasm instantiation failed:", err);
-			const appDiv = document.getElementById('app');
-			appDiv.innerHTML = '<h2 style="color: red;">Error</h2><p>Failed to load WebAssembly module. Check console.</p>';
-        });
-    </script>
-</body>
-</html>
+// liveReloadScript is injected into served HTML pages so the browser
+// connects to the livereload socket and reloads (or shows a build-error
+// overlay) when the dev server pushes a message.
+const liveReloadScript = `
+<script>
+(function () {
+	var proto = location.protocol === "https:" ? "wss://" : "ws://";
+	var socket = new WebSocket(proto + location.host + "` + liveReloadPath + `");
+	socket.onmessage = function (event) {
+		var msg;
+		try {
+			msg = JSON.parse(event.data);
+		} catch (e) {
+			return;
+		}
+		if (msg.type === "reload") {
+			location.reload();
+		} else if (msg.type === "error") {
+			showBuildError(msg.message);
+		}
+	};
+	function showBuildError(message) {
+		var overlay = document.getElementById("__goflow_error_overlay__");
+		if (!overlay) {
+			overlay = document.createElement("pre");
+			overlay.id = "__goflow_error_overlay__";
+			overlay.style.cssText = "position:fixed;inset:0;margin:0;padding:2rem;background:rgba(20,0,0,0.92);" +
+				"color:#ff8080;font-family:monospace;white-space:pre-wrap;overflow:auto;z-index:999999;";
+			document.body.appendChild(overlay);
+		}
+		overlay.textContent = "GoFlow build error:\n\n" + message;
+	}
+})();
+</script>
 `
 
-func goModTemplate(projectName string) string {
-	return fmt.Sprintf(`
-module %s
+// injectLiveReload wraps a handler so that any HTML response it produces
+// has the livereload client script inserted before </body>.
+func injectLiveReload(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := newBufferedResponseWriter()
+		next.ServeHTTP(rec, r)
+
+		body := rec.body.Bytes()
+		if rec.status == http.StatusOK && bytes.Contains(body, []byte("</body>")) {
+			body = bytes.Replace(body, []byte("</body>"), []byte(liveReloadScript+"</body>"), 1)
+		}
 
-go 1.22
-`, projectName)
+		header := w.Header()
+		for key, values := range rec.Header() {
+			header[key] = values
+		}
+		header.Set("Content-Length", fmt.Sprintf("%d", len(body)))
+		w.WriteHeader(rec.status)
+		w.Write(body)
+	})
 }
 
-func readmeTemplate(projectName string) string {
-	return fmt.Sprintf(`
-# %s
+// bufferedResponseWriter records a handler's response so it can be
+// rewritten before being sent to the real client.
+type bufferedResponseWriter struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
 
-This project was generated by the GoFlow CLI.
+func newBufferedResponseWriter() *bufferedResponseWriter {
+	return &bufferedResponseWriter{header: make(http.Header), status: http.StatusOK}
+}
 
-## Development
+func (w *bufferedResponseWriter) Header() http.Header { return w.header }
 
-`, projectName)
-}
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) { return w.body.Write(b) }
 
-const gitignoreTemplate = `
-# Compiled Wasm file
-app.wasm
+func (w *bufferedResponseWriter) WriteHeader(status int) { w.status = status }
 
-# JS glue file
-wasm_exec.js
-`
+// copyWasmExec finds and copies the wasm_exec.js file.
+func copyWasmExec() error {
+	goRoot := runtime.GOROOT()
+	if goRoot == "" {
+		return fmt.Errorf("GOROOT environment variable is not set")
+	}
+
+	srcPath := filepath.Join(goRoot, "lib", "wasm", "wasm_exec.js")
+	destPath := "wasm_exec.js"
+
+	srcFile, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("could not open source file %s: %w", srcPath, err)
+	}
+	defer srcFile.Close()
+
+	destFile, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("could not create destination file %s: %w", destPath, err)
+	}
+	defer destFile.Close()
+
+	_, err = io.Copy(destFile, srcFile)
+	if err != nil {
+		return fmt.Errorf("could not copy file contents: %w", err)
+	}
+	return nil
+}
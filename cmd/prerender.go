@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// ssrMarker is the placeholder a project's index.html carries for
+// "goflow prerender" to splice server-rendered HTML into.
+const ssrMarker = "<!--goflow-ssr-->"
+
+// prerenderCmd represents the prerender command
+var prerenderCmd = &cobra.Command{
+	Use:   "prerender",
+	Short: "Server-side renders the app into index.html for first paint and SEO.",
+	Long: `Compiles the project twice: once normally to app.wasm, and once as a
+native binary (build tag goflow_ssr) that calls a user-supplied
+App() *vdom.VNode entrypoint defined outside main.go, since main.go
+imports syscall/js and can't build for a native target. The rendered
+HTML replaces the <!--goflow-ssr--> marker in index.html, and the
+container it sits in is marked data-goflow-ssr so the client can call
+renderer.Hydrate instead of NewRenderer+Render on first paint.`,
+	Run: runPrerender,
+}
+
+func init() {
+	rootCmd.AddCommand(prerenderCmd)
+}
+
+func runPrerender(cmd *cobra.Command, args []string) {
+	if _, err := os.Stat("main.go"); os.IsNotExist(err) {
+		fmt.Println("❌ No main.go file found. Are you in a GoFlow project directory?")
+		os.Exit(1)
+	}
+
+	fmt.Println("Building Go code to WebAssembly...")
+	if err := buildWasm(os.Stderr); err != nil {
+		fmt.Printf("❌ Build failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("✅ Build successful.")
+
+	if err := copyWasmExec(); err != nil {
+		fmt.Printf("❌ Failed to copy wasm_exec.js: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("✅ Copied wasm_exec.js.")
+
+	fmt.Println("Server-rendering the app...")
+	html, err := renderSSR()
+	if err != nil {
+		fmt.Printf("❌ Prerender failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := injectSSR(html); err != nil {
+		fmt.Printf("❌ Could not update index.html: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("✅ Wrote server-rendered markup into index.html.")
+}
+
+// renderSSR builds the project's App() entrypoint as a native binary
+// tagged goflow_ssr and runs it, returning the HTML it writes to
+// stdout. It builds from a temporary copy of the project with main.go
+// left out, since main.go imports syscall/js and only compiles for
+// GOOS=js GOARCH=wasm.
+func renderSSR() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	buildDir, err := os.MkdirTemp("", "goflow-ssr-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(buildDir)
+
+	if err := copyProjectExceptMain(wd, buildDir); err != nil {
+		return "", fmt.Errorf("copying project: %w", err)
+	}
+
+	entryPath := filepath.Join(buildDir, "ssr_entry.go")
+	if err := os.WriteFile(entryPath, []byte(ssrEntrySource), 0644); err != nil {
+		return "", err
+	}
+
+	binPath := filepath.Join(buildDir, "ssr_render")
+	buildCmd := exec.Command("go", "build", "-tags", "goflow_ssr", "-o", binPath, ".")
+	buildCmd.Dir = buildDir
+	buildCmd.Stderr = os.Stderr
+	if err := buildCmd.Run(); err != nil {
+		return "", fmt.Errorf("compiling SSR entrypoint (does the project define func App() *vdom.VNode outside main.go?): %w", err)
+	}
+
+	var stdout bytes.Buffer
+	runCmd := exec.Command(binPath)
+	runCmd.Stdout = &stdout
+	runCmd.Stderr = os.Stderr
+	if err := runCmd.Run(); err != nil {
+		return "", fmt.Errorf("running SSR entrypoint: %w", err)
+	}
+
+	return stdout.String(), nil
+}
+
+// ssrEntrySource is the main package goflow writes alongside a copy of
+// the project to produce its native, SSR-only binary.
+const ssrEntrySource = `//go:build goflow_ssr
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/AureClai/goflow/renderer/ssr"
+)
+
+func main() {
+	if err := ssr.Render(os.Stdout, App(), os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`
+
+// copyProjectExceptMain copies src into dst, skipping main.go and the
+// dist/ and .git directories.
+func copyProjectExceptMain(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if d.IsDir() {
+			if rel == distDir || rel == ".git" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dst, rel), 0755)
+		}
+		if rel == "main.go" {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), content, 0644)
+	})
+}
+
+// injectSSR replaces ssrMarker in index.html with html and marks its
+// container data-goflow-ssr so the client knows to hydrate rather than
+// render cold.
+func injectSSR(html string) error {
+	content, err := os.ReadFile("index.html")
+	if err != nil {
+		return fmt.Errorf("reading index.html: %w", err)
+	}
+
+	if !bytes.Contains(content, []byte(ssrMarker)) {
+		return fmt.Errorf("index.html has no %s marker to render into", ssrMarker)
+	}
+
+	rewritten := strings.Replace(string(content), ssrMarker, html, 1)
+	rewritten = strings.Replace(rewritten, `id="app">`, `id="app" data-goflow-ssr>`, 1)
+
+	return os.WriteFile("index.html", []byte(rewritten), 0644)
+}
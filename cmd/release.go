@@ -0,0 +1,399 @@
+package cmd
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+
+	"github.com/andybalholm/brotli"
+)
+
+// distDir is where a release build writes its hashed, pre-compressed
+// assets and manifest.
+const distDir = "dist"
+
+// releaseManifestName is the asset-hash manifest writeManifest
+// produces. It's deliberately not "manifest.json": a pwa project has
+// its own web-app manifest.json (see cmd/templates/pwa), which
+// copyProjectAssets copies into dist/ verbatim, and the two must not
+// collide.
+const releaseManifestName = "goflow-manifest.json"
+
+// packagedServerMainTemplate is the entrypoint compiled into the
+// self-contained hosting binaries produced by --target; it serves the
+// dist directory, embedded via embed.FS, on :8080.
+//
+//go:embed packaging/server_main.go.tmpl
+var packagedServerMainTemplate string
+
+// runRelease builds a production Wasm bundle into dist/: minified and
+// trimmed, optionally run through wasm-opt, content-hashed so it can be
+// served with long cache lifetimes, and pre-compressed with gzip and
+// brotli. If targets is non-empty it also cross-compiles a small
+// self-hosted server binary with the bundle embedded, one zip per
+// os/arch pair.
+func runRelease(projectName string, targets []string) error {
+	if err := os.MkdirAll(distDir, 0755); err != nil {
+		return fmt.Errorf("could not create %s: %w", distDir, err)
+	}
+
+	fmt.Println("Building release Wasm bundle...")
+	wasmPath, err := compileReleaseWasm()
+	if err != nil {
+		return fmt.Errorf("build failed: %w", err)
+	}
+	defer os.Remove(wasmPath)
+	fmt.Println("✅ Build successful.")
+
+	optimized, err := optimizeWasm(wasmPath)
+	if err != nil {
+		fmt.Printf("⚠️ wasm-opt failed, shipping the unoptimized build: %v\n", err)
+	} else if optimized {
+		fmt.Println("✅ Optimized with wasm-opt.")
+	}
+
+	assets := make(map[string]string, 3)
+
+	hashedWasm, err := hashAndCopy(wasmPath, "app", ".wasm")
+	if err != nil {
+		return err
+	}
+	assets["app.wasm"] = hashedWasm
+
+	wasmExecSrc := filepath.Join(runtime.GOROOT(), "lib", "wasm", "wasm_exec.js")
+	hashedWasmExec, err := hashAndCopy(wasmExecSrc, "wasm_exec", ".js")
+	if err != nil {
+		return err
+	}
+	assets["wasm_exec.js"] = hashedWasmExec
+
+	if err := writeReleaseIndexHTML(assets); err != nil {
+		return err
+	}
+	assets["index.html"] = "index.html"
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if err := copyProjectAssets(wd, distDir); err != nil {
+		return fmt.Errorf("copying static assets: %w", err)
+	}
+	fmt.Println("✅ Copied static assets.")
+
+	for logical, hashed := range assets {
+		if err := precompress(filepath.Join(distDir, hashed)); err != nil {
+			return fmt.Errorf("compressing %s: %w", logical, err)
+		}
+	}
+	fmt.Println("✅ Wrote gzip/brotli sidecars.")
+
+	if err := writeManifest(assets); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Wrote %s\n", filepath.Join(distDir, releaseManifestName))
+
+	for _, target := range targets {
+		if err := packageTarget(target, projectName); err != nil {
+			return fmt.Errorf("packaging %s: %w", target, err)
+		}
+	}
+
+	return nil
+}
+
+// compileReleaseWasm builds the project's main.go to a temporary Wasm
+// file with debug info and symbols stripped and absolute paths trimmed.
+func compileReleaseWasm() (string, error) {
+	out := filepath.Join(os.TempDir(), fmt.Sprintf("goflow-release-%d.wasm", os.Getpid()))
+
+	buildCmd := exec.Command("go", "build", "-trimpath", "-ldflags=-s -w", "-o", out, ".")
+	buildCmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	buildCmd.Stdout = os.Stdout
+	buildCmd.Stderr = os.Stderr
+	if err := buildCmd.Run(); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// optimizeWasm runs wasm-opt -Oz over wasmPath in place if it's on
+// PATH, reporting via its bool return whether optimization happened.
+func optimizeWasm(wasmPath string) (bool, error) {
+	if _, err := exec.LookPath("wasm-opt"); err != nil {
+		return false, nil
+	}
+
+	optimized := wasmPath + ".opt"
+	cmd := exec.Command("wasm-opt", "-Oz", "--enable-bulk-memory", wasmPath, "-o", optimized)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return false, err
+	}
+	return true, os.Rename(optimized, wasmPath)
+}
+
+// hashAndCopy copies srcPath into distDir under a name that embeds the
+// first 8 bytes of its SHA-256 hash, e.g. hashAndCopy(path, "app",
+// ".wasm") -> "app.1a2b3c4d5e6f7890.wasm". It returns the hashed name.
+func hashAndCopy(srcPath, base, ext string) (string, error) {
+	content, err := os.ReadFile(srcPath)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", srcPath, err)
+	}
+
+	sum := sha256.Sum256(content)
+	hashedName := fmt.Sprintf("%s.%x%s", base, sum[:8], ext)
+
+	if err := os.WriteFile(filepath.Join(distDir, hashedName), content, 0644); err != nil {
+		return "", fmt.Errorf("writing %s: %w", hashedName, err)
+	}
+	return hashedName, nil
+}
+
+// writeReleaseIndexHTML rewrites the project's index.html so it
+// references the hashed asset names, and writes the result into
+// distDir.
+func writeReleaseIndexHTML(assets map[string]string) error {
+	content, err := os.ReadFile("index.html")
+	if err != nil {
+		return fmt.Errorf("reading index.html: %w", err)
+	}
+
+	rewritten := string(content)
+	rewritten = strings.ReplaceAll(rewritten, "app.wasm", assets["app.wasm"])
+	rewritten = strings.ReplaceAll(rewritten, "wasm_exec.js", assets["wasm_exec.js"])
+
+	return os.WriteFile(filepath.Join(distDir, "index.html"), []byte(rewritten), 0644)
+}
+
+// precompress writes gzip and brotli sidecars (path+".gz", path+".br")
+// next to path.
+func precompress(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	gzPath := path + ".gz"
+	gzFile, err := os.Create(gzPath)
+	if err != nil {
+		return err
+	}
+	defer gzFile.Close()
+	gz := gzip.NewWriter(gzFile)
+	if _, err := gz.Write(content); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	brPath := path + ".br"
+	brFile, err := os.Create(brPath)
+	if err != nil {
+		return err
+	}
+	defer brFile.Close()
+	br := brotli.NewWriter(brFile)
+	if _, err := br.Write(content); err != nil {
+		return err
+	}
+	return br.Close()
+}
+
+// writeManifest writes distDir/releaseManifestName, mapping each
+// logical asset name to the hashed name it was actually written under.
+func writeManifest(assets map[string]string) error {
+	payload, err := json.MarshalIndent(assets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(distDir, releaseManifestName), payload, 0644)
+}
+
+// copyProjectAssets copies src into dst verbatim, skipping Go source
+// files, go.mod/go.sum, the .git and dist directories, the unhashed
+// app.wasm and wasm_exec.js, and the project's own index.html
+// (runRelease writes hashed and rewritten ones separately). This is what gets a pwa project's manifest.json and
+// sw.js — otherwise untouched by the hashed-asset pipeline above —
+// into the release bundle.
+func copyProjectAssets(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if d.IsDir() {
+			if rel == distDir || rel == ".git" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dst, rel), 0755)
+		}
+		switch rel {
+		case "main.go", "go.mod", "go.sum", "index.html", "app.wasm", "wasm_exec.js":
+			return nil
+		}
+		if filepath.Ext(rel) == ".go" {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), content, 0644)
+	})
+}
+
+// packageTarget cross-compiles a small net/http server with dist/
+// embedded via embed.FS for the given "os/arch" target, and zips the
+// resulting binary into dist/packages/.
+func packageTarget(target, projectName string) error {
+	goos, goarch, ok := strings.Cut(target, "/")
+	if !ok {
+		return fmt.Errorf("invalid target %q, expected the form os/arch", target)
+	}
+
+	buildDir, err := os.MkdirTemp("", "goflow-package-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(buildDir)
+
+	// packages/ holds the zips packageTarget itself writes as each target
+	// finishes; excluding it keeps one target's archive from ending up
+	// embedded inside the next target's binary.
+	if err := copyDir(distDir, filepath.Join(buildDir, "dist"), map[string]bool{"packages": true}); err != nil {
+		return fmt.Errorf("copying dist: %w", err)
+	}
+
+	goMod := fmt.Sprintf("module goflowserver\n\ngo %s\n", defaultGoVersion)
+	if err := os.WriteFile(filepath.Join(buildDir, "go.mod"), []byte(goMod), 0644); err != nil {
+		return err
+	}
+
+	mainSrc, err := renderPackagedServerMain(projectName)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(buildDir, "main.go"), []byte(mainSrc), 0644); err != nil {
+		return err
+	}
+
+	binName := "server"
+	if goos == "windows" {
+		binName += ".exe"
+	}
+
+	buildCmd := exec.Command("go", "build", "-o", binName, ".")
+	buildCmd.Dir = buildDir
+	buildCmd.Env = append(os.Environ(), "GOOS="+goos, "GOARCH="+goarch)
+	buildCmd.Stdout = os.Stdout
+	buildCmd.Stderr = os.Stderr
+	if err := buildCmd.Run(); err != nil {
+		return fmt.Errorf("compiling server: %w", err)
+	}
+
+	packagesDir := filepath.Join(distDir, "packages")
+	if err := os.MkdirAll(packagesDir, 0755); err != nil {
+		return err
+	}
+	zipPath := filepath.Join(packagesDir, fmt.Sprintf("%s-%s-%s.zip", projectName, goos, goarch))
+	if err := zipBinary(filepath.Join(buildDir, binName), binName, zipPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Packaged %s\n", zipPath)
+	return nil
+}
+
+func renderPackagedServerMain(projectName string) (string, error) {
+	tmpl, err := template.New("server_main.go").Parse(packagedServerMainTemplate)
+	if err != nil {
+		return "", fmt.Errorf("parsing packaged server template: %w", err)
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, templateData{ProjectName: projectName}); err != nil {
+		return "", fmt.Errorf("rendering packaged server template: %w", err)
+	}
+	return out.String(), nil
+}
+
+// copyDir copies src into dst, skipping any directory (relative to src)
+// named in skipDirs.
+func copyDir(src, dst string, skipDirs map[string]bool) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			if rel != "." && skipDirs[rel] {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0755)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, 0644)
+	})
+}
+
+func zipBinary(srcPath, nameInZip, destZipPath string) error {
+	zipFile, err := os.Create(destZipPath)
+	if err != nil {
+		return err
+	}
+	defer zipFile.Close()
+
+	w := zip.NewWriter(zipFile)
+	defer w.Close()
+
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return err
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = nameInZip
+	header.Method = zip.Deflate
+
+	entry, err := w.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(srcPath)
+	if err != nil {
+		return err
+	}
+	_, err = entry.Write(content)
+	return err
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// embeddedTemplates holds GoFlow's built-in project templates, one
+// subdirectory per template name.
+//
+//go:embed all:templates
+var embeddedTemplates embed.FS
+
+// embeddedTemplatesRoot is the directory inside embeddedTemplates that
+// holds the built-in templates.
+const embeddedTemplatesRoot = "templates"
+
+// defaultGoVersion is the Go version written into a scaffolded
+// project's go.mod.
+const defaultGoVersion = "1.22"
+
+// templateData is the context every template file is executed against.
+type templateData struct {
+	ProjectName string
+	GoVersion   string
+	ModulePath  string
+}
+
+// templateSource is a filesystem holding one subdirectory per template,
+// rooted at root. This makes template discovery pluggable: builtinTemplates
+// reads from the embedded FS, externalTemplates from a user-supplied
+// --template-dir.
+type templateSource struct {
+	fsys fs.FS
+	root string
+}
+
+func builtinTemplates() templateSource {
+	return templateSource{fsys: embeddedTemplates, root: embeddedTemplatesRoot}
+}
+
+func externalTemplates(dir string) templateSource {
+	return templateSource{fsys: os.DirFS(dir), root: "."}
+}
+
+// list returns the names of the templates available in src, sorted.
+func (src templateSource) list() ([]string, error) {
+	entries, err := fs.ReadDir(src.fsys, src.root)
+	if err != nil {
+		return nil, fmt.Errorf("could not read templates from %s: %w", src.root, err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// render walks src's "<root>/<name>" directory into destDir: files
+// ending in .tmpl are executed against data and written without the
+// suffix, everything else is copied byte for byte. It returns the
+// destination paths it created.
+func (src templateSource) render(name, destDir string, data templateData) ([]string, error) {
+	templateRoot := path.Join(src.root, name)
+
+	var created []string
+	err := fs.WalkDir(src.fsys, templateRoot, func(srcPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel := strings.TrimPrefix(srcPath, templateRoot+"/")
+		destPath := filepath.Join(destDir, filepath.FromSlash(strings.TrimSuffix(rel, ".tmpl")))
+
+		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+			return err
+		}
+
+		content, err := fs.ReadFile(src.fsys, srcPath)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasSuffix(srcPath, ".tmpl") {
+			if err := renderTemplateFile(srcPath, string(content), destPath, data); err != nil {
+				return err
+			}
+		} else if err := os.WriteFile(destPath, content, 0644); err != nil {
+			return err
+		}
+
+		created = append(created, destPath)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func renderTemplateFile(srcPath, content, destPath string, data templateData) error {
+	tmpl, err := template.New(path.Base(srcPath)).Parse(content)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", srcPath, err)
+	}
+
+	file, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", destPath, err)
+	}
+	defer file.Close()
+
+	if err := tmpl.Execute(file, data); err != nil {
+		return fmt.Errorf("rendering %s: %w", srcPath, err)
+	}
+	return nil
+}
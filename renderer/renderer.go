@@ -3,13 +3,28 @@
 package renderer
 
 import (
+	"strings"
 	"syscall/js"
 
 	"github.com/AureClai/goflow/vdom"
 )
 
+// mountedNode pairs a VNode with the real DOM node it produced, so the
+// next render can diff against what is actually on screen instead of
+// tearing it down. Event listeners are kept as js.Func so they can be
+// removed and Release()d without leaking.
+type mountedNode struct {
+	vnode     *vdom.VNode
+	dom       js.Value
+	listeners map[string]js.Func
+	children  []*mountedNode
+}
+
+// Renderer mounts a GoFlow vdom tree into a container element and keeps
+// it in sync across renders via DOM diff/patch rather than full rebuilds.
 type Renderer struct {
 	container js.Value
+	tree      *mountedNode
 }
 
 func NewRenderer(containerID string) *Renderer {
@@ -20,49 +35,416 @@ func NewRenderer(containerID string) *Renderer {
 	}
 }
 
-func (r *Renderer) Render(vnode *vdom.VNode) {
-	// Clear container and render new tree
-	r.container.Set("innerHTML", "")
-	if vnode != nil {
-		domNode := r.createDomNode(vnode)
-		r.container.Call("appendChild", domNode)
+// Render reconciles the currently mounted tree with next, patching the
+// real DOM in place: unchanged nodes are left alone, changed props are
+// patched, each element's listeners are re-bound to next's handlers, and
+// only nodes that truly differ are replaced. The first call clears the container first, so static
+// placeholder markup (e.g. a template's "Loading..." message) doesn't
+// linger next to the mounted app.
+func (r *Renderer) Render(next *vdom.VNode) {
+	if r.tree == nil {
+		clearContainer(r.container)
+	}
+	r.tree = r.diff(r.tree, next, r.container, 0)
+}
+
+// clearContainer removes every child currently in parentDOM.
+func clearContainer(parentDOM js.Value) {
+	for domChildCount(parentDOM) > 0 {
+		parentDOM.Call("removeChild", domChildAt(parentDOM, 0))
 	}
 }
 
-func (r *Renderer) createDomNode(vnode *vdom.VNode) js.Value {
+// Hydrate adopts a server-rendered DOM subtree already present inside
+// containerID instead of rebuilding it from scratch: it walks the
+// existing DOM in lockstep with vnode, attaching event listeners and
+// only creating/replacing nodes where the two structures diverge. Use
+// it for pages built with "goflow prerender", whose container carries a
+// data-goflow-ssr attribute; call NewRenderer and Render for a cold
+// mount otherwise.
+func Hydrate(containerID string, vnode *vdom.VNode) *Renderer {
+	r := NewRenderer(containerID)
+	r.tree = r.hydrateChild(vnode, r.container, 0)
+	return r
+}
+
+// hydrateChild adopts the DOM node already sitting at index inside
+// parentDOM (if any) for vnode, recursing into children in lockstep. If
+// there's no existing node there, or it doesn't match vnode's type/tag,
+// it falls back to mounting vnode fresh.
+func (r *Renderer) hydrateChild(vnode *vdom.VNode, parentDOM js.Value, index int) *mountedNode {
+	if vnode == nil {
+		return nil
+	}
+
+	existing := domChildAt(parentDOM, index)
+	if !existing.Truthy() || !domMatches(existing, vnode) {
+		mounted := r.mount(vnode)
+		if existing.Truthy() {
+			parentDOM.Call("replaceChild", mounted.dom, existing)
+		} else {
+			insertAt(parentDOM, mounted.dom, index)
+		}
+		return mounted
+	}
+
+	m := &mountedNode{vnode: vnode, dom: existing}
+
+	if vnode.Type == vdom.VNodeElement {
+		if len(vnode.EventHandlers) > 0 {
+			m.listeners = make(map[string]js.Func, len(vnode.EventHandlers))
+			for event, handler := range vnode.EventHandlers {
+				m.listeners[event] = addListener(existing, event, handler)
+			}
+		}
+
+		m.children = make([]*mountedNode, 0, len(vnode.Children))
+		for i, child := range vnode.Children {
+			m.children = append(m.children, r.hydrateChild(child, existing, i))
+		}
+		for domChildCount(existing) > len(vnode.Children) {
+			existing.Call("removeChild", domChildAt(existing, len(vnode.Children)))
+		}
+	}
+
+	return m
+}
+
+// domMatches reports whether dom is a plausible match for vnode: a text
+// node for VNodeText, or an element with the same tag for VNodeElement.
+func domMatches(dom js.Value, vnode *vdom.VNode) bool {
+	switch vnode.Type {
+	case vdom.VNodeText:
+		return dom.Get("nodeType").Int() == 3
+	case vdom.VNodeElement:
+		return dom.Get("nodeType").Int() == 1 && strings.EqualFold(dom.Get("tagName").String(), vnode.Tag)
+	default:
+		return false
+	}
+}
+
+func domChildAt(parentDOM js.Value, index int) js.Value {
+	children := parentDOM.Get("childNodes")
+	if index < children.Get("length").Int() {
+		return children.Index(index)
+	}
+	return js.Value{}
+}
+
+func domChildCount(parentDOM js.Value) int {
+	return parentDOM.Get("childNodes").Get("length").Int()
+}
+
+// diff reconciles old (the previously mounted node at this position, or
+// nil) against next (the new vnode, or nil), as the child at index of
+// parentDOM. It returns the mountedNode now occupying that position, or
+// nil if nothing is there anymore.
+func (r *Renderer) diff(old *mountedNode, next *vdom.VNode, parentDOM js.Value, index int) *mountedNode {
+	if next == nil {
+		if old != nil {
+			r.unmount(parentDOM, old)
+		}
+		return nil
+	}
+
+	if old == nil {
+		mounted := r.mount(next)
+		insertAt(parentDOM, mounted.dom, index)
+		return mounted
+	}
+
+	if old.vnode.Type != next.Type || (next.Type == vdom.VNodeElement && old.vnode.Tag != next.Tag) {
+		replacement := r.mount(next)
+		parentDOM.Call("replaceChild", replacement.dom, old.dom)
+		r.release(old)
+		return replacement
+	}
+
+	switch next.Type {
+	case vdom.VNodeText:
+		if old.vnode.Text != next.Text {
+			old.dom.Set("nodeValue", next.Text)
+		}
+	case vdom.VNodeElement:
+		r.patchProps(old, next)
+		r.patchEvents(old, next)
+		r.diffChildren(old, next)
+	}
+	old.vnode = next
+	return old
+}
+
+// mount creates a fresh DOM node (and, recursively, its subtree) for
+// vnode, wiring up event listeners as it goes.
+func (r *Renderer) mount(vnode *vdom.VNode) *mountedNode {
 	document := js.Global().Get("document")
+	m := &mountedNode{vnode: vnode}
 
 	switch vnode.Type {
 	case vdom.VNodeText:
-		return document.Call("createTextNode", vnode.Text)
+		m.dom = document.Call("createTextNode", vnode.Text)
 
 	case vdom.VNodeElement:
 		element := document.Call("createElement", vnode.Tag)
 
-		// Set properties
 		for key, value := range vnode.Props {
 			element.Call("setAttribute", key, value)
 		}
 
-		// Add event listeners
-		for event, handler := range vnode.EventHandlers {
-			element.Call("addEventListener", event, js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-				handler()
-				return nil
-			}))
+		if len(vnode.EventHandlers) > 0 {
+			m.listeners = make(map[string]js.Func, len(vnode.EventHandlers))
+			for event, handler := range vnode.EventHandlers {
+				m.listeners[event] = addListener(element, event, handler)
+			}
 		}
 
-		// Append children
+		m.children = make([]*mountedNode, 0, len(vnode.Children))
 		for _, child := range vnode.Children {
-			childNode := r.createDomNode(child)
-			if childNode.Truthy() {
-				element.Call("appendChild", childNode)
+			childMounted := r.mount(child)
+			element.Call("appendChild", childMounted.dom)
+			m.children = append(m.children, childMounted)
+		}
+
+		m.dom = element
+	}
+
+	return m
+}
+
+// unmount removes m's DOM node from parentDOM and releases its
+// listeners (and those of its subtree).
+func (r *Renderer) unmount(parentDOM js.Value, m *mountedNode) {
+	parentDOM.Call("removeChild", m.dom)
+	r.release(m)
+}
+
+// release detaches and Release()s every listener in m's subtree without
+// touching the DOM, for use after the node has already been
+// removed/replaced by the caller.
+func (r *Renderer) release(m *mountedNode) {
+	for event, fn := range m.listeners {
+		m.dom.Call("removeEventListener", event, fn)
+		fn.Release()
+	}
+	for _, child := range m.children {
+		r.release(child)
+	}
+}
+
+func addListener(element js.Value, event string, handler func()) js.Func {
+	fn := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		handler()
+		return nil
+	})
+	element.Call("addEventListener", event, fn)
+	return fn
+}
+
+// patchProps reconciles next.Props against the props old was last
+// rendered with, issuing only the setAttribute/removeAttribute calls
+// needed to bring the DOM node up to date.
+func (r *Renderer) patchProps(old *mountedNode, next *vdom.VNode) {
+	for key, value := range next.Props {
+		if oldValue, ok := old.vnode.Props[key]; !ok || oldValue != value {
+			old.dom.Call("setAttribute", key, value)
+		}
+	}
+	for key := range old.vnode.Props {
+		if _, ok := next.Props[key]; !ok {
+			old.dom.Call("removeAttribute", key)
+		}
+	}
+}
+
+// patchEvents reconciles next.EventHandlers against old's currently
+// attached listeners, removing (and Release()ing) the ones that are
+// gone and replacing every remaining one with a listener bound to next's
+// handler, so closures captured by the previous render are not called.
+func (r *Renderer) patchEvents(old *mountedNode, next *vdom.VNode) {
+	if old.listeners == nil {
+		old.listeners = make(map[string]js.Func)
+	}
+	for event, fn := range old.listeners {
+		old.dom.Call("removeEventListener", event, fn)
+		fn.Release()
+		delete(old.listeners, event)
+	}
+	for event, handler := range next.EventHandlers {
+		old.listeners[event] = addListener(old.dom, event, handler)
+	}
+}
+
+// diffChildren reconciles old's mounted children against next.Children,
+// using the keyed algorithm when every child on both sides has a Key and
+// falling back to index-based diffing otherwise.
+func (r *Renderer) diffChildren(old *mountedNode, next *vdom.VNode) {
+	if allKeyed(old.children, next.Children) {
+		old.children = r.diffKeyedChildren(old.dom, old.children, next.Children)
+		return
+	}
+
+	count := len(old.children)
+	if len(next.Children) > count {
+		count = len(next.Children)
+	}
+
+	merged := make([]*mountedNode, 0, len(next.Children))
+	for i := 0; i < count; i++ {
+		var oldChild *mountedNode
+		if i < len(old.children) {
+			oldChild = old.children[i]
+		}
+		var nextChild *vdom.VNode
+		if i < len(next.Children) {
+			nextChild = next.Children[i]
+		}
+		if m := r.diff(oldChild, nextChild, old.dom, i); m != nil {
+			merged = append(merged, m)
+		}
+	}
+	old.children = merged
+}
+
+func allKeyed(oldChildren []*mountedNode, nextChildren []*vdom.VNode) bool {
+	if len(oldChildren) == 0 || len(nextChildren) == 0 {
+		return false
+	}
+	for _, c := range oldChildren {
+		if c.vnode.Key == "" {
+			return false
+		}
+	}
+	for _, v := range nextChildren {
+		if v.Key == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// diffKeyedChildren reconciles a keyed child list using a two-pointer
+// scan from both ends (matching same-position and swapped-ends moves
+// without touching the DOM), falling back to a keyed lookup for
+// children that were reordered into the middle. Matched old nodes are
+// moved with insertBefore instead of being recreated.
+func (r *Renderer) diffKeyedChildren(parentDOM js.Value, oldChildren []*mountedNode, nextChildren []*vdom.VNode) []*mountedNode {
+	oldStart, oldEnd := 0, len(oldChildren)-1
+	newStart, newEnd := 0, len(nextChildren)-1
+
+	result := make([]*mountedNode, len(nextChildren))
+	consumed := make(map[int]bool, len(oldChildren))
+
+	oldKeyIndex := make(map[string]int, len(oldChildren))
+	for i, c := range oldChildren {
+		oldKeyIndex[c.vnode.Key] = i
+	}
+
+	for oldStart <= oldEnd && newStart <= newEnd {
+		// Old children already matched by the keyed lookup below have
+		// been moved elsewhere; skip them so they're never used as an
+		// insertion reference or matched twice.
+		if consumed[oldStart] {
+			oldStart++
+			continue
+		}
+		if consumed[oldEnd] {
+			oldEnd--
+			continue
+		}
+
+		switch {
+		case oldChildren[oldStart].vnode.Key == nextChildren[newStart].Key:
+			result[newStart] = r.diff(oldChildren[oldStart], nextChildren[newStart], parentDOM, newStart)
+			consumed[oldStart] = true
+			oldStart++
+			newStart++
+
+		case oldChildren[oldEnd].vnode.Key == nextChildren[newEnd].Key:
+			result[newEnd] = r.diff(oldChildren[oldEnd], nextChildren[newEnd], parentDOM, newEnd)
+			consumed[oldEnd] = true
+			oldEnd--
+			newEnd--
+
+		case oldChildren[oldStart].vnode.Key == nextChildren[newEnd].Key:
+			// Node moved from the front to the back.
+			m := r.diff(oldChildren[oldStart], nextChildren[newEnd], parentDOM, newEnd)
+			insertAfterIndex(parentDOM, m.dom, result, newEnd)
+			result[newEnd] = m
+			consumed[oldStart] = true
+			oldStart++
+			newEnd--
+
+		case oldChildren[oldEnd].vnode.Key == nextChildren[newStart].Key:
+			// Node moved from the back to the front.
+			m := r.diff(oldChildren[oldEnd], nextChildren[newStart], parentDOM, newStart)
+			insertBeforeIndex(parentDOM, m.dom, oldChildren, oldStart)
+			result[newStart] = m
+			consumed[oldEnd] = true
+			oldEnd--
+			newStart++
+
+		default:
+			// Not at either end: look the new child up by key anywhere
+			// in the remaining old range, or mount it fresh.
+			if oldIndex, ok := oldKeyIndex[nextChildren[newStart].Key]; ok && oldIndex >= oldStart && oldIndex <= oldEnd {
+				m := r.diff(oldChildren[oldIndex], nextChildren[newStart], parentDOM, newStart)
+				insertBeforeIndex(parentDOM, m.dom, oldChildren, oldStart)
+				result[newStart] = m
+				consumed[oldIndex] = true
+			} else {
+				m := r.diff(nil, nextChildren[newStart], parentDOM, newStart)
+				insertBeforeIndex(parentDOM, m.dom, oldChildren, oldStart)
+				result[newStart] = m
 			}
+			newStart++
+		}
+	}
 
+	// Remaining new children with no old counterpart: mount them.
+	for ; newStart <= newEnd; newStart++ {
+		m := r.diff(nil, nextChildren[newStart], parentDOM, newStart)
+		result[newStart] = m
+	}
+
+	// Remaining old children with no new counterpart: unmount them.
+	for i := oldStart; i <= oldEnd; i++ {
+		if !consumed[i] {
+			r.unmount(parentDOM, oldChildren[i])
 		}
+	}
+
+	return result
+}
 
-		return element
+// insertAt inserts dom as parentDOM's child at position index.
+func insertAt(parentDOM, dom js.Value, index int) {
+	ref := domChildAt(parentDOM, index)
+	if ref.Truthy() {
+		parentDOM.Call("insertBefore", dom, ref)
+		return
 	}
+	parentDOM.Call("appendChild", dom)
+}
 
-	return js.Null()
+// insertBeforeIndex moves dom so it sits directly before the old child
+// currently at fromIndex (or appends it if that child no longer exists).
+func insertBeforeIndex(parentDOM, dom js.Value, oldChildren []*mountedNode, fromIndex int) {
+	if fromIndex < len(oldChildren) {
+		parentDOM.Call("insertBefore", dom, oldChildren[fromIndex].dom)
+		return
+	}
+	parentDOM.Call("appendChild", dom)
+}
+
+// insertAfterIndex moves dom so it sits directly before the first new
+// child already placed in result[afterIndex+1:], falling back to
+// appendChild when there is no later sibling yet.
+func insertAfterIndex(parentDOM, dom js.Value, result []*mountedNode, afterIndex int) {
+	for i := afterIndex + 1; i < len(result); i++ {
+		if result[i] != nil {
+			parentDOM.Call("insertBefore", dom, result[i].dom)
+			return
+		}
+	}
+	parentDOM.Call("appendChild", dom)
 }
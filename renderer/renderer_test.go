@@ -0,0 +1,199 @@
+//go:build js && wasm
+
+package renderer
+
+import (
+	"fmt"
+	"strings"
+	"syscall/js"
+	"testing"
+
+	"github.com/AureClai/goflow/vdom"
+)
+
+// newFakeDocument returns a minimal DOM-like object, built entirely out
+// of JS values, with just enough of createElement/createTextNode/
+// getElementById/appendChild/insertBefore/removeChild/replaceChild/
+// setAttribute/addEventListener for the reconciler to drive against
+// without a real browser. It registers a single root element under id
+// "app".
+func newFakeDocument() js.Value {
+	array := js.Global().Get("Array")
+	object := js.Global().Get("Object")
+	registry := map[string]js.Value{}
+
+	newNode := func(nodeType int, tag string) js.Value {
+		node := object.New()
+		node.Set("nodeType", nodeType)
+		node.Set("tagName", strings.ToUpper(tag))
+		node.Set("childNodes", array.New())
+		node.Set("_listeners", object.New())
+
+		node.Set("appendChild", js.FuncOf(func(this js.Value, args []js.Value) any {
+			this.Get("childNodes").Call("push", args[0])
+			return args[0]
+		}))
+		node.Set("insertBefore", js.FuncOf(func(this js.Value, args []js.Value) any {
+			newChild, ref := args[0], args[1]
+			if idx := fakeIndexOf(this.Get("childNodes"), ref); idx >= 0 {
+				this.Get("childNodes").Call("splice", idx, 0, newChild)
+			} else {
+				this.Get("childNodes").Call("push", newChild)
+			}
+			return newChild
+		}))
+		node.Set("removeChild", js.FuncOf(func(this js.Value, args []js.Value) any {
+			if idx := fakeIndexOf(this.Get("childNodes"), args[0]); idx >= 0 {
+				this.Get("childNodes").Call("splice", idx, 1)
+			}
+			return args[0]
+		}))
+		node.Set("replaceChild", js.FuncOf(func(this js.Value, args []js.Value) any {
+			newChild, oldChild := args[0], args[1]
+			if idx := fakeIndexOf(this.Get("childNodes"), oldChild); idx >= 0 {
+				this.Get("childNodes").Call("splice", idx, 1, newChild)
+			}
+			return oldChild
+		}))
+		node.Set("setAttribute", js.FuncOf(func(this js.Value, args []js.Value) any {
+			this.Set("attr_"+args[0].String(), args[1])
+			return nil
+		}))
+		node.Set("removeAttribute", js.FuncOf(func(this js.Value, args []js.Value) any {
+			this.Delete("attr_" + args[0].String())
+			return nil
+		}))
+		node.Set("addEventListener", js.FuncOf(func(this js.Value, args []js.Value) any {
+			event, fn := args[0].String(), args[1]
+			listeners := this.Get("_listeners")
+			bucket := listeners.Get(event)
+			if !bucket.Truthy() {
+				bucket = array.New()
+				listeners.Set(event, bucket)
+			}
+			bucket.Call("push", fn)
+			return nil
+		}))
+		node.Set("removeEventListener", js.FuncOf(func(this js.Value, args []js.Value) any {
+			bucket := this.Get("_listeners").Get(args[0].String())
+			if bucket.Truthy() {
+				if idx := fakeIndexOf(bucket, args[1]); idx >= 0 {
+					bucket.Call("splice", idx, 1)
+				}
+			}
+			return nil
+		}))
+		return node
+	}
+
+	document := object.New()
+	document.Set("createElement", js.FuncOf(func(this js.Value, args []js.Value) any {
+		return newNode(1, args[0].String())
+	}))
+	document.Set("createTextNode", js.FuncOf(func(this js.Value, args []js.Value) any {
+		node := newNode(3, "")
+		node.Set("nodeValue", args[0])
+		return node
+	}))
+	document.Set("getElementById", js.FuncOf(func(this js.Value, args []js.Value) any {
+		if el, ok := registry[args[0].String()]; ok {
+			return el
+		}
+		return js.Null()
+	}))
+
+	registry["app"] = newNode(1, "div")
+	return document
+}
+
+func fakeIndexOf(arr, value js.Value) int {
+	length := arr.Get("length").Int()
+	for i := 0; i < length; i++ {
+		if arr.Index(i).Equal(value) {
+			return i
+		}
+	}
+	return -1
+}
+
+// newTestRenderer installs a fresh fake document as the JS global and
+// returns a Renderer mounted on its "app" root, which already carries
+// static placeholder content the way a scaffolded index.html does.
+func newTestRenderer(t *testing.T) *Renderer {
+	t.Helper()
+	document := newFakeDocument()
+	js.Global().Set("document", document)
+
+	root := document.Call("getElementById", "app")
+	root.Call("appendChild", document.Call("createTextNode", "Loading..."))
+
+	return NewRenderer("app")
+}
+
+func TestRenderClearsPreexistingContainerContent(t *testing.T) {
+	r := newTestRenderer(t)
+
+	r.Render(vdom.Element("h1", nil, nil, vdom.Text("Hello")))
+
+	children := r.container.Get("childNodes")
+	if n := children.Get("length").Int(); n != 1 {
+		t.Fatalf("container has %d children after first Render, want 1 (placeholder should be cleared)", n)
+	}
+	if tag := children.Index(0).Get("tagName").String(); tag != "H1" {
+		t.Fatalf("container's only child is %q, want H1", tag)
+	}
+}
+
+func TestRenderPatchesInPlaceWithoutRecreating(t *testing.T) {
+	r := newTestRenderer(t)
+
+	r.Render(vdom.Element("div", map[string]string{"class": "a"}, nil, vdom.Text("one")))
+	first := r.container.Get("childNodes").Index(0)
+	first.Set("markedByTest", true)
+
+	r.Render(vdom.Element("div", map[string]string{"class": "b"}, nil, vdom.Text("two")))
+
+	second := r.container.Get("childNodes").Index(0)
+	if !second.Get("markedByTest").Truthy() {
+		t.Fatal("div was recreated on a prop/text-only change, want it patched in place")
+	}
+	if got := second.Get("attr_class").String(); got != "b" {
+		t.Fatalf("class attribute = %q, want %q", got, "b")
+	}
+	if got := second.Get("childNodes").Index(0).Get("nodeValue").String(); got != "two" {
+		t.Fatalf("text content = %q, want %q", got, "two")
+	}
+}
+
+func TestDiffKeyedChildrenReordersWithoutRecreating(t *testing.T) {
+	r := newTestRenderer(t)
+
+	item := func(key, text string) *vdom.VNode {
+		return &vdom.VNode{Type: vdom.VNodeElement, Tag: "li", Key: key, Children: []*vdom.VNode{vdom.Text(text)}}
+	}
+	list := func(items ...*vdom.VNode) *vdom.VNode {
+		return &vdom.VNode{Type: vdom.VNodeElement, Tag: "ul", Children: items}
+	}
+
+	r.Render(list(item("a", "A"), item("b", "B"), item("c", "C")))
+
+	ul := r.container.Get("childNodes").Index(0)
+	for i := 0; i < 3; i++ {
+		ul.Get("childNodes").Index(i).Set("markedByTest", js.ValueOf(fmt.Sprintf("orig-%d", i)))
+	}
+
+	r.Render(list(item("c", "C"), item("a", "A"), item("b", "B")))
+
+	ul = r.container.Get("childNodes").Index(0)
+	wantOrder := []string{"orig-2", "orig-0", "orig-1"}
+	for i, want := range wantOrder {
+		child := ul.Get("childNodes").Index(i)
+		marker := child.Get("markedByTest")
+		if !marker.Truthy() {
+			t.Fatalf("child at position %d was recreated, want the reordered original node", i)
+		}
+		if got := marker.String(); got != want {
+			t.Fatalf("child at position %d carries marker %q, want %q (keyed reorder should move nodes, not remount them)", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Package ssr renders a GoFlow vdom tree to static HTML on the server,
+// for a "goflow prerender" build's first paint. Unlike the renderer
+// package it carries no js && wasm build tag, since it runs as a native
+// binary at build time rather than in the browser.
+package ssr
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+
+	"github.com/AureClai/goflow/vdom"
+)
+
+// voidElements never get a closing tag or children, per the HTML spec.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// Render writes vnode, and its subtree, to w as HTML: text nodes are
+// escaped, Props are serialized as attributes, and elements are closed
+// unless they're void. EventHandlers can't run server-side, so they're
+// left off the output; instead, each element with skipped handlers is
+// reported to warnings as one line of text naming them all (pass nil to
+// discard them).
+func Render(w io.Writer, vnode *vdom.VNode, warnings io.Writer) error {
+	if vnode == nil {
+		return nil
+	}
+
+	switch vnode.Type {
+	case vdom.VNodeText:
+		_, err := io.WriteString(w, html.EscapeString(vnode.Text))
+		return err
+
+	case vdom.VNodeElement:
+		return renderElement(w, vnode, warnings)
+
+	default:
+		return fmt.Errorf("ssr: unknown VNode type %v", vnode.Type)
+	}
+}
+
+func renderElement(w io.Writer, vnode *vdom.VNode, warnings io.Writer) error {
+	if _, err := fmt.Fprintf(w, "<%s", vnode.Tag); err != nil {
+		return err
+	}
+
+	propNames := make([]string, 0, len(vnode.Props))
+	for name := range vnode.Props {
+		propNames = append(propNames, name)
+	}
+	sort.Strings(propNames)
+	for _, name := range propNames {
+		if _, err := fmt.Fprintf(w, ` %s="%s"`, name, html.EscapeString(vnode.Props[name])); err != nil {
+			return err
+		}
+	}
+
+	warnSkippedHandlers(vnode, warnings)
+
+	if voidElements[vnode.Tag] {
+		_, err := io.WriteString(w, " />")
+		return err
+	}
+
+	if _, err := io.WriteString(w, ">"); err != nil {
+		return err
+	}
+	for _, child := range vnode.Children {
+		if err := Render(w, child, warnings); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "</%s>", vnode.Tag)
+	return err
+}
+
+// warnSkippedHandlers reports vnode's event handlers to warnings, if
+// any, since SSR has no way to run them.
+func warnSkippedHandlers(vnode *vdom.VNode, warnings io.Writer) {
+	if len(vnode.EventHandlers) == 0 || warnings == nil {
+		return
+	}
+
+	events := make([]string, 0, len(vnode.EventHandlers))
+	for event := range vnode.EventHandlers {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+	fmt.Fprintf(warnings, "ssr: skipping event handler(s) %v on <%s>: they can't run server-side\n", events, vnode.Tag)
+}
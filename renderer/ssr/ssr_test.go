@@ -0,0 +1,88 @@
+package ssr
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/AureClai/goflow/vdom"
+)
+
+func render(t *testing.T, vnode *vdom.VNode, warnings io.Writer) string {
+	t.Helper()
+	var out strings.Builder
+	if err := Render(&out, vnode, warnings); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	return out.String()
+}
+
+func TestRenderEscapesText(t *testing.T) {
+	got := render(t, vdom.Text(`<script>&"'</script>`), nil)
+	want := `&lt;script&gt;&amp;&#34;&#39;&lt;/script&gt;`
+	if got != want {
+		t.Fatalf("Render(text) = %q, want %q", got, want)
+	}
+}
+
+func TestRenderEscapesAttributes(t *testing.T) {
+	vnode := vdom.Element("div", map[string]string{"title": `<>&"'`}, nil)
+	got := render(t, vnode, nil)
+	want := `<div title="&lt;&gt;&amp;&#34;&#39;"></div>`
+	if got != want {
+		t.Fatalf("Render(attrs) = %q, want %q", got, want)
+	}
+}
+
+func TestRenderSelfClosesVoidElements(t *testing.T) {
+	vnode := vdom.Element("img", map[string]string{"src": "cat.png"}, nil, vdom.Text("ignored"))
+	got := render(t, vnode, nil)
+	want := `<img src="cat.png" />`
+	if got != want {
+		t.Fatalf("Render(void element) = %q, want %q (children must not be rendered either)", got, want)
+	}
+}
+
+func TestRenderNestsChildren(t *testing.T) {
+	vnode := vdom.Element("ul", nil, nil,
+		vdom.Element("li", nil, nil, vdom.Text("one")),
+		vdom.Element("li", nil, nil, vdom.Text("two")),
+	)
+	got := render(t, vnode, nil)
+	want := `<ul><li>one</li><li>two</li></ul>`
+	if got != want {
+		t.Fatalf("Render(nested) = %q, want %q", got, want)
+	}
+}
+
+func TestRenderWarnsOnSkippedHandlers(t *testing.T) {
+	vnode := &vdom.VNode{
+		Type: vdom.VNodeElement,
+		Tag:  "button",
+		EventHandlers: map[string]func(){
+			"click":     func() {},
+			"mouseover": func() {},
+		},
+	}
+	var warnings strings.Builder
+	render(t, vnode, &warnings)
+
+	want := "ssr: skipping event handler(s) [click mouseover] on <button>: they can't run server-side\n"
+	if got := warnings.String(); got != want {
+		t.Fatalf("warnings = %q, want %q", got, want)
+	}
+}
+
+func TestRenderDiscardsWarningsWhenNilWriterPassed(t *testing.T) {
+	vnode := &vdom.VNode{
+		Type:          vdom.VNodeElement,
+		Tag:           "button",
+		EventHandlers: map[string]func(){"click": func() {}},
+	}
+	// Render already passes nil as warnings in the other tests above;
+	// this just asserts it doesn't panic and still renders the element.
+	got := render(t, vnode, nil)
+	if want := "<button></button>"; got != want {
+		t.Fatalf("Render = %q, want %q", got, want)
+	}
+}
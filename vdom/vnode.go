@@ -0,0 +1,55 @@
+// Package vdom defines GoFlow's virtual DOM tree: a lightweight,
+// platform-independent description of an element tree that the
+// renderer package mounts to real DOM nodes in the browser.
+package vdom
+
+// VNodeType identifies what kind of node a VNode represents.
+type VNodeType int
+
+const (
+	// VNodeText is a plain text node; only Text is meaningful.
+	VNodeText VNodeType = iota
+	// VNodeElement is a tagged element; Tag, Props, EventHandlers and
+	// Children are meaningful.
+	VNodeElement
+)
+
+// VNode is a single node in a GoFlow virtual DOM tree.
+type VNode struct {
+	Type VNodeType
+
+	// Tag is the HTML tag name, e.g. "div". Only set for VNodeElement.
+	Tag string
+	// Text is the node's text content. Only set for VNodeText.
+	Text string
+
+	// Props are the element's attributes, set via setAttribute.
+	Props map[string]string
+	// EventHandlers maps a DOM event name (e.g. "click") to the
+	// callback invoked when it fires.
+	EventHandlers map[string]func()
+	// Children are the node's child nodes, in document order.
+	Children []*VNode
+
+	// Key identifies a child across renders so the reconciler can
+	// match and move existing DOM nodes instead of recreating them.
+	// Leave empty to fall back to index-based diffing.
+	Key string
+}
+
+// Text returns a leaf VNode wrapping a text string.
+func Text(text string) *VNode {
+	return &VNode{Type: VNodeText, Text: text}
+}
+
+// Element returns a VNode for the given tag, props, event handlers and
+// children.
+func Element(tag string, props map[string]string, handlers map[string]func(), children ...*VNode) *VNode {
+	return &VNode{
+		Type:          VNodeElement,
+		Tag:           tag,
+		Props:         props,
+		EventHandlers: handlers,
+		Children:      children,
+	}
+}